@@ -0,0 +1,172 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package loadaware
+
+import (
+	"math"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	slov1alpha1 "github.com/koordinator-sh/koordinator/apis/slo/v1alpha1"
+)
+
+// BalanceWeights controls how much each resource contributes to the dominant-resource-spread score.
+// A resource absent from the map is ignored when computing imbalance.
+type BalanceWeights map[corev1.ResourceName]int64
+
+// resourceNames returns the set of resources weights has an entry for, e.g. to select which
+// resources computeUtilizations should consider for the balance score.
+func (w BalanceWeights) resourceNames() sets.Set[corev1.ResourceName] {
+	names := sets.New[corev1.ResourceName]()
+	for resourceName := range w {
+		names.Insert(resourceName)
+	}
+	return names
+}
+
+// resourceUtilization is a resource's used/allocatable pair expressed as a 0-100 percentage.
+type resourceUtilization struct {
+	resourceName corev1.ResourceName
+	percent      float64
+}
+
+// computeUtilizations returns the per-resource utilization percentages, combining the node's current
+// aggregated usage with the incoming pod's requests. resourceNames selects which resources to
+// include; a nil or empty set falls back to every resource reported in usage or podRequests, which is
+// what the plain usage-based score (usageBasedScore) uses so it works independently of BalanceWeights,
+// a feature an operator may not have opted into.
+func computeUtilizations(usage *slov1alpha1.ResourceMap, allocatable corev1.ResourceList, podRequests corev1.ResourceList, resourceNames sets.Set[corev1.ResourceName]) []resourceUtilization {
+	if resourceNames.Len() == 0 {
+		resourceNames = sets.New[corev1.ResourceName]()
+		if usage != nil {
+			for resourceName := range usage.ResourceList {
+				resourceNames.Insert(resourceName)
+			}
+		}
+		for resourceName := range podRequests {
+			resourceNames.Insert(resourceName)
+		}
+	}
+
+	var utilizations []resourceUtilization
+	for resourceName := range resourceNames {
+		allocatableQuantity, ok := allocatable[resourceName]
+		if !ok || allocatableQuantity.IsZero() {
+			continue
+		}
+		totalValue := getResourceValue(resourceName, allocatableQuantity)
+
+		var usedValue int64
+		if usage != nil {
+			if quantity, ok := usage.ResourceList[resourceName]; ok {
+				usedValue = getResourceValue(resourceName, quantity)
+			}
+		}
+		if requestQuantity, ok := podRequests[resourceName]; ok {
+			usedValue += getResourceValue(resourceName, requestQuantity)
+		}
+
+		utilizations = append(utilizations, resourceUtilization{
+			resourceName: resourceName,
+			percent:      float64(usedValue) / float64(totalValue) * 100,
+		})
+	}
+	return utilizations
+}
+
+// computeImbalanceScore returns a 0-100 score, higher meaning more balanced, derived from the
+// standard deviation of the node's per-resource utilization percentages after the incoming pod is
+// placed. It is meant to be combined with the usage-based score via a configurable weight so that,
+// together with low-utilization filtering, nodes that would end up with CPU% and memory% close
+// together are preferred -- similar in spirit to usage-based + balanced-resource-allocation scoring.
+func computeImbalanceScore(usage *slov1alpha1.ResourceMap, allocatable corev1.ResourceList, podRequests corev1.ResourceList, weights BalanceWeights) int64 {
+	utilizations := computeUtilizations(usage, allocatable, podRequests, weights.resourceNames())
+	if len(utilizations) < 2 {
+		return 100
+	}
+
+	// A resource weighted at 0 (or a negative value) is excluded from the imbalance calculation
+	// entirely, the same way an absent weight excludes it from computeUtilizations -- it must not
+	// be silently promoted to the default weight of 1, or a user could never de-emphasize a resource.
+	var weightedMean, weightSum float64
+	for _, u := range utilizations {
+		weight := float64(weights[u.resourceName])
+		if weight <= 0 {
+			continue
+		}
+		weightedMean += u.percent * weight
+		weightSum += weight
+	}
+	if weightSum == 0 {
+		return 100
+	}
+	weightedMean /= weightSum
+
+	var variance float64
+	for _, u := range utilizations {
+		weight := float64(weights[u.resourceName])
+		if weight <= 0 {
+			continue
+		}
+		diff := u.percent - weightedMean
+		variance += weight * diff * diff
+	}
+	variance /= weightSum
+	stddev := math.Sqrt(variance)
+
+	// A stddev of 0 is perfectly balanced (score 100); a stddev of 100 or more (maximally spread
+	// across a 0-100 scale) bottoms out at 0.
+	score := 100 - stddev
+	if score < 0 {
+		score = 0
+	}
+	return int64(math.Round(score))
+}
+
+// usageBasedScore returns a 0-100 score from the same per-resource utilization percentages
+// computeImbalanceScore consumes, favoring lower average utilization -- the counterpart to the
+// balance score that combineScores blends it with.
+func usageBasedScore(utilizations []resourceUtilization) int64 {
+	if len(utilizations) == 0 {
+		return 100
+	}
+	var sum float64
+	for _, u := range utilizations {
+		sum += u.percent
+	}
+	score := 100 - sum/float64(len(utilizations))
+	if score < 0 {
+		score = 0
+	}
+	if score > 100 {
+		score = 100
+	}
+	return int64(math.Round(score))
+}
+
+// combineScores blends the usage-based score with the imbalance score according to balanceWeight
+// (0-100, the percentage of the final score attributed to balance).
+func combineScores(usageScore, imbalanceScore, balanceWeightPercent int64) int64 {
+	if balanceWeightPercent <= 0 {
+		return usageScore
+	}
+	if balanceWeightPercent >= 100 {
+		return imbalanceScore
+	}
+	return (usageScore*(100-balanceWeightPercent) + imbalanceScore*balanceWeightPercent) / 100
+}
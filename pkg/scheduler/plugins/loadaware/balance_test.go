@@ -0,0 +1,84 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package loadaware
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	slov1alpha1 "github.com/koordinator-sh/koordinator/apis/slo/v1alpha1"
+)
+
+func TestComputeImbalanceScoreZeroWeightIsExcluded(t *testing.T) {
+	allocatable := corev1.ResourceList{
+		corev1.ResourceCPU:    resource.MustParse("100"),
+		corev1.ResourceMemory: resource.MustParse("100Gi"),
+	}
+	usage := &slov1alpha1.ResourceMap{ResourceList: corev1.ResourceList{
+		corev1.ResourceCPU:    resource.MustParse("80"),
+		corev1.ResourceMemory: resource.MustParse("10Gi"),
+	}}
+
+	// With both resources weighted equally, the 80/10 spread should be penalized.
+	balanced := computeImbalanceScore(usage, allocatable, nil, BalanceWeights{
+		corev1.ResourceCPU:    1,
+		corev1.ResourceMemory: 1,
+	})
+	assert.Less(t, balanced, int64(100))
+
+	// A resource explicitly weighted at 0 must be excluded from the calculation, not treated as
+	// weight 1 -- so with memory zeroed out, only CPU remains and the score is perfectly balanced.
+	zeroed := computeImbalanceScore(usage, allocatable, nil, BalanceWeights{
+		corev1.ResourceCPU:    1,
+		corev1.ResourceMemory: 0,
+	})
+	assert.Equal(t, int64(100), zeroed)
+}
+
+func TestComputeUtilizationsNilResourceNamesDerivesFromUsage(t *testing.T) {
+	allocatable := corev1.ResourceList{
+		corev1.ResourceCPU:    resource.MustParse("100"),
+		corev1.ResourceMemory: resource.MustParse("100Gi"),
+	}
+	usage := &slov1alpha1.ResourceMap{ResourceList: corev1.ResourceList{
+		corev1.ResourceCPU: resource.MustParse("90"),
+	}}
+	podRequests := corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("10Gi")}
+
+	// With no resource set supplied (the base usage score's case, independent of BalanceWeights),
+	// every resource reported by usage or podRequests must still be considered.
+	utilizations := computeUtilizations(usage, allocatable, podRequests, nil)
+	assert.Len(t, utilizations, 2)
+	assert.Equal(t, int64(50), usageBasedScore(utilizations))
+}
+
+func TestUsageBasedScore(t *testing.T) {
+	assert.Equal(t, int64(100), usageBasedScore(nil))
+	assert.Equal(t, int64(70), usageBasedScore([]resourceUtilization{
+		{resourceName: corev1.ResourceCPU, percent: 20},
+		{resourceName: corev1.ResourceMemory, percent: 40},
+	}))
+}
+
+func TestCombineScores(t *testing.T) {
+	assert.Equal(t, int64(80), combineScores(80, 20, 0))
+	assert.Equal(t, int64(20), combineScores(80, 20, 100))
+	assert.Equal(t, int64(50), combineScores(80, 20, 50))
+}
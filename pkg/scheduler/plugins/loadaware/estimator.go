@@ -0,0 +1,293 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package loadaware
+
+import (
+	"sync"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	"github.com/koordinator-sh/koordinator/apis/extension"
+	slov1alpha1 "github.com/koordinator-sh/koordinator/apis/slo/v1alpha1"
+	"github.com/koordinator-sh/koordinator/pkg/util"
+)
+
+// newResourceQuantity rebuilds a resource.Quantity from the internal int64 representation used by
+// getResourceValue (milli-value for CPU, value for everything else).
+func newResourceQuantity(resourceName corev1.ResourceName, value int64) resource.Quantity {
+	if resourceName == corev1.ResourceCPU {
+		return *resource.NewMilliQuantity(value, resource.DecimalSI)
+	}
+	return *resource.NewQuantity(value, resource.DecimalSI)
+}
+
+// PodUsageEstimator estimates the resource usage a pod will contribute to its node once it starts
+// running. It is consulted for pods in the `estimatedPods` set, i.e. pods that have already been
+// assigned to a node but have not yet shown up in that node's NodeMetric, so their estimated usage
+// has to substitute for a real measurement.
+type PodUsageEstimator interface {
+	// Name returns a short identifier used in logs and metrics.
+	Name() string
+	// EstimatePodUsage returns the estimated usage for pod, or false if this estimator has no
+	// estimate for it (e.g. no history yet), so the caller can fall through to the next estimator.
+	EstimatePodUsage(pod *corev1.Pod) (corev1.ResourceList, bool)
+}
+
+// RequestsEstimator estimates pod usage as its resource requests, scaled by scaleFactor. This
+// matches the plugin's original behavior before estimators were pluggable.
+type RequestsEstimator struct {
+	// ScaleFactorPercent scales down requests, e.g. 65 means estimate usage at 65% of requests.
+	ScaleFactorPercent int64
+}
+
+func (e *RequestsEstimator) Name() string { return "Requests" }
+
+func (e *RequestsEstimator) EstimatePodUsage(pod *corev1.Pod) (corev1.ResourceList, bool) {
+	requests := util.GetPodRequest(pod)
+	if len(requests) == 0 {
+		return nil, false
+	}
+	scale := e.ScaleFactorPercent
+	if scale <= 0 {
+		scale = 100
+	}
+	estimated := make(corev1.ResourceList, len(requests))
+	for resourceName, quantity := range requests {
+		value := getResourceValue(resourceName, quantity) * scale / 100
+		estimated[resourceName] = newResourceQuantity(resourceName, value)
+	}
+	return estimated, true
+}
+
+// historicalUsageWindow is a single workload's recent per-resource P95 samples, indexed oldest-first.
+type historicalUsageWindow struct {
+	samples []corev1.ResourceList
+	maxSize int
+}
+
+func (w *historicalUsageWindow) add(usage corev1.ResourceList) {
+	w.samples = append(w.samples, usage)
+	if len(w.samples) > w.maxSize {
+		w.samples = w.samples[len(w.samples)-w.maxSize:]
+	}
+}
+
+func (w *historicalUsageWindow) p95() corev1.ResourceList {
+	if len(w.samples) == 0 {
+		return nil
+	}
+	byResource := map[corev1.ResourceName][]int64{}
+	for _, sample := range w.samples {
+		for resourceName, quantity := range sample {
+			byResource[resourceName] = append(byResource[resourceName], getResourceValue(resourceName, quantity))
+		}
+	}
+	result := make(corev1.ResourceList, len(byResource))
+	for resourceName, values := range byResource {
+		result[resourceName] = newResourceQuantity(resourceName, percentile95(values))
+	}
+	return result
+}
+
+func percentile95(values []int64) int64 {
+	sorted := append([]int64(nil), values...)
+	for i := 1; i < len(sorted); i++ {
+		for j := i; j > 0 && sorted[j-1] > sorted[j]; j-- {
+			sorted[j-1], sorted[j] = sorted[j], sorted[j-1]
+		}
+	}
+	index := len(sorted) * 95 / 100
+	if index >= len(sorted) {
+		index = len(sorted) - 1
+	}
+	return sorted[index]
+}
+
+// HistoricalEstimator estimates pod usage from a sliding window of per-workload P95 usage observed
+// in NodeMetric.Status.PodsMetric, keyed by the pod's owner reference so that new replicas of an
+// already-seen workload inherit its observed footprint instead of starting from zero.
+type HistoricalEstimator struct {
+	mu         sync.RWMutex
+	windowSize int
+	history    map[types.UID]*historicalUsageWindow
+}
+
+// NewHistoricalEstimator creates a HistoricalEstimator that retains up to windowSize samples per workload.
+func NewHistoricalEstimator(windowSize int) *HistoricalEstimator {
+	if windowSize <= 0 {
+		windowSize = 10
+	}
+	return &HistoricalEstimator{
+		windowSize: windowSize,
+		history:    make(map[types.UID]*historicalUsageWindow),
+	}
+}
+
+func (e *HistoricalEstimator) Name() string { return "Historical" }
+
+// Observe records a new usage sample for the workload whose controller UID is ownerUID; an empty
+// ownerUID is ignored. Callers feed this from
+// NodeMetric.Status.PodsMetric as new reports arrive.
+func (e *HistoricalEstimator) Observe(ownerUID types.UID, usage corev1.ResourceList) {
+	if ownerUID == "" {
+		return
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	window, ok := e.history[ownerUID]
+	if !ok {
+		window = &historicalUsageWindow{maxSize: e.windowSize}
+		e.history[ownerUID] = window
+	}
+	window.add(usage)
+}
+
+func (e *HistoricalEstimator) EstimatePodUsage(pod *corev1.Pod) (corev1.ResourceList, bool) {
+	ownerUID := controllerOwnerUID(pod)
+	if ownerUID == "" {
+		return nil, false
+	}
+	e.mu.RLock()
+	window, ok := e.history[ownerUID]
+	e.mu.RUnlock()
+	if !ok {
+		return nil, false
+	}
+	usage := window.p95()
+	return usage, len(usage) > 0
+}
+
+// PredictionGetter looks up a TimeSeriesPrediction-style forecast for a workload owner. It is
+// satisfied by a lister over the prediction CR so this package doesn't need to depend on its types
+// directly.
+type PredictionGetter func(ownerUID types.UID) (corev1.ResourceList, bool)
+
+// PredictionEstimator estimates pod usage from a per-workload prediction CR, e.g. a forecast
+// produced by an external time-series model.
+type PredictionEstimator struct {
+	GetPrediction PredictionGetter
+}
+
+func (e *PredictionEstimator) Name() string { return "Prediction" }
+
+func (e *PredictionEstimator) EstimatePodUsage(pod *corev1.Pod) (corev1.ResourceList, bool) {
+	if e.GetPrediction == nil {
+		return nil, false
+	}
+	ownerUID := controllerOwnerUID(pod)
+	if ownerUID == "" {
+		return nil, false
+	}
+	return e.GetPrediction(ownerUID)
+}
+
+// controllerOwnerUID returns the UID of the pod's managing controller, if any.
+func controllerOwnerUID(pod *corev1.Pod) types.UID {
+	for _, ref := range pod.OwnerReferences {
+		if ref.Controller != nil && *ref.Controller {
+			return ref.UID
+		}
+	}
+	return ""
+}
+
+// EstimatorChain tries each estimator in order for a given priority class, falling back to the
+// next one when the preferred estimator has no estimate yet (e.g. a HistoricalEstimator with no
+// observations for a brand-new workload).
+type EstimatorChain struct {
+	// ByPriorityClass selects the estimator chain to use for a pod's priority class. The
+	// extension.PriorityProd/PriorityMid/PriorityBE values are used as keys; DefaultChain is
+	// used when a pod's priority class has no entry.
+	ByPriorityClass map[extension.PriorityClass][]PodUsageEstimator
+	// DefaultChain is used for pods whose priority class has no entry in ByPriorityClass.
+	DefaultChain []PodUsageEstimator
+}
+
+// Estimate runs the configured chain for the pod's priority class, returning the first estimator
+// that produces a usage estimate.
+func (c *EstimatorChain) Estimate(pod *corev1.Pod, priority extension.PriorityClass) corev1.ResourceList {
+	chain := c.ByPriorityClass[priority]
+	if len(chain) == 0 {
+		chain = c.DefaultChain
+	}
+	for _, estimator := range chain {
+		if usage, ok := estimator.EstimatePodUsage(pod); ok {
+			return usage
+		}
+	}
+	return nil
+}
+
+// EstimateAssumedPodsUsage returns the combined estimated usage of assumedPods that have not yet
+// been reported in nodeMetric.Status.PodsMetric, using chain to estimate each such pod. Pods already
+// present in PodsMetric are skipped since their real usage is already reflected in the node's
+// aggregated usage and estimating them again would double-count them. A nil chain disables
+// estimation entirely, returning nil.
+func EstimateAssumedPodsUsage(nodeMetric *slov1alpha1.NodeMetric, assumedPods []*corev1.Pod, priorityOf func(*corev1.Pod) extension.PriorityClass, chain *EstimatorChain) corev1.ResourceList {
+	if chain == nil || len(assumedPods) == 0 {
+		return nil
+	}
+	reported := sets.New[types.NamespacedName]()
+	for _, podMetric := range nodeMetric.Status.PodsMetric {
+		reported.Insert(types.NamespacedName{Namespace: podMetric.Namespace, Name: podMetric.Name})
+	}
+
+	total := make(corev1.ResourceList)
+	for _, pod := range assumedPods {
+		name := types.NamespacedName{Namespace: pod.Namespace, Name: pod.Name}
+		if reported.Has(name) {
+			continue
+		}
+		if usage := chain.Estimate(pod, priorityOf(pod)); usage != nil {
+			util.AddResourceList(total, usage)
+		}
+	}
+	return total
+}
+
+// ownerUIDsOf indexes pods by name to their controller owner UID, for feeding a HistoricalEstimator
+// via feedHistoricalEstimator. Pods without a controller owner reference are omitted.
+func ownerUIDsOf(pods []*corev1.Pod) map[types.NamespacedName]types.UID {
+	if len(pods) == 0 {
+		return nil
+	}
+	result := make(map[types.NamespacedName]types.UID, len(pods))
+	for _, pod := range pods {
+		if uid := controllerOwnerUID(pod); uid != "" {
+			result[types.NamespacedName{Namespace: pod.Namespace, Name: pod.Name}] = uid
+		}
+	}
+	return result
+}
+
+// feedHistoricalEstimator updates a HistoricalEstimator from the latest PodsMetric report of a NodeMetric.
+func feedHistoricalEstimator(estimator *HistoricalEstimator, nodeMetric *slov1alpha1.NodeMetric, ownerOf map[types.NamespacedName]types.UID) {
+	if estimator == nil {
+		return
+	}
+	for _, podMetric := range nodeMetric.Status.PodsMetric {
+		name := types.NamespacedName{Namespace: podMetric.Namespace, Name: podMetric.Name}
+		ownerUID, ok := ownerOf[name]
+		if !ok {
+			continue
+		}
+		estimator.Observe(ownerUID, podMetric.PodUsage.ResourceList)
+	}
+}
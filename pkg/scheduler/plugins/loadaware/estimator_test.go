@@ -0,0 +1,134 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package loadaware
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/koordinator-sh/koordinator/apis/extension"
+	slov1alpha1 "github.com/koordinator-sh/koordinator/apis/slo/v1alpha1"
+)
+
+func TestPercentile95(t *testing.T) {
+	assert.Equal(t, int64(1), percentile95([]int64{1}))
+	assert.Equal(t, int64(3), percentile95([]int64{3, 1, 2}))
+	// Ties at the 95th index must resolve deterministically to the same sorted value.
+	assert.Equal(t, int64(5), percentile95([]int64{5, 5, 5, 1, 2, 3, 4}))
+}
+
+func TestRequestsEstimator(t *testing.T) {
+	e := &RequestsEstimator{ScaleFactorPercent: 50}
+	pod := &corev1.Pod{Spec: corev1.PodSpec{Containers: []corev1.Container{{
+		Resources: corev1.ResourceRequirements{Requests: corev1.ResourceList{
+			corev1.ResourceCPU: resource.MustParse("2"),
+		}},
+	}}}}
+
+	usage, ok := e.EstimatePodUsage(pod)
+	assert.True(t, ok)
+	assert.Equal(t, int64(1000), usage.Cpu().MilliValue())
+
+	empty := &RequestsEstimator{}
+	_, ok = empty.EstimatePodUsage(&corev1.Pod{})
+	assert.False(t, ok)
+}
+
+func TestHistoricalEstimator(t *testing.T) {
+	e := NewHistoricalEstimator(2)
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			OwnerReferences: []metav1.OwnerReference{{UID: "owner-1", Controller: boolPtr(true)}},
+		},
+	}
+
+	_, ok := e.EstimatePodUsage(pod)
+	assert.False(t, ok, "no history yet")
+
+	e.Observe("owner-1", corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("1")})
+	e.Observe("owner-1", corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("3")})
+	// windowSize is 2, so the third sample should evict the first.
+	e.Observe("owner-1", corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("5")})
+
+	usage, ok := e.EstimatePodUsage(pod)
+	assert.True(t, ok)
+	assert.Equal(t, int64(5000), usage.Cpu().MilliValue())
+}
+
+func boolPtr(v bool) *bool { return &v }
+
+func TestEstimatorChainFallsThrough(t *testing.T) {
+	historical := NewHistoricalEstimator(5)
+	requests := &RequestsEstimator{}
+	chain := &EstimatorChain{DefaultChain: []PodUsageEstimator{historical, requests}}
+
+	pod := &corev1.Pod{Spec: corev1.PodSpec{Containers: []corev1.Container{{
+		Resources: corev1.ResourceRequirements{Requests: corev1.ResourceList{
+			corev1.ResourceCPU: resource.MustParse("1"),
+		}},
+	}}}}
+
+	// historical has no data yet, so the chain should fall through to requests.
+	usage := chain.Estimate(pod, extension.PriorityBE)
+	assert.Equal(t, int64(1000), usage.Cpu().MilliValue())
+}
+
+func TestEstimateAssumedPodsUsage(t *testing.T) {
+	chain := &EstimatorChain{DefaultChain: []PodUsageEstimator{&RequestsEstimator{}}}
+	priorityOf := func(*corev1.Pod) extension.PriorityClass { return extension.PriorityBE }
+
+	reportedPod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "reported"}}
+	unreportedPod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "unreported"},
+		Spec: corev1.PodSpec{Containers: []corev1.Container{{
+			Resources: corev1.ResourceRequirements{Requests: corev1.ResourceList{
+				corev1.ResourceCPU: resource.MustParse("2"),
+			}},
+		}}},
+	}
+
+	nodeMetric := &slov1alpha1.NodeMetric{Status: slov1alpha1.NodeMetricStatus{
+		PodsMetric: []*slov1alpha1.PodMetricInfo{
+			{Namespace: "ns", Name: "reported"},
+		},
+	}}
+
+	assert.Nil(t, EstimateAssumedPodsUsage(nodeMetric, nil, priorityOf, chain))
+	assert.Nil(t, EstimateAssumedPodsUsage(nodeMetric, []*corev1.Pod{reportedPod}, priorityOf, nil))
+
+	usage := EstimateAssumedPodsUsage(nodeMetric, []*corev1.Pod{reportedPod, unreportedPod}, priorityOf, chain)
+	assert.Equal(t, int64(2000), usage.Cpu().MilliValue())
+}
+
+func TestOwnerUIDsOf(t *testing.T) {
+	withOwner := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+		Namespace:       "ns",
+		Name:            "a",
+		OwnerReferences: []metav1.OwnerReference{{UID: "owner-1", Controller: boolPtr(true)}},
+	}}
+	withoutOwner := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "b"}}
+
+	result := ownerUIDsOf([]*corev1.Pod{withOwner, withoutOwner})
+	assert.Equal(t, types.UID("owner-1"), result[types.NamespacedName{Namespace: "ns", Name: "a"}])
+	_, ok := result[types.NamespacedName{Namespace: "ns", Name: "b"}]
+	assert.False(t, ok)
+}
@@ -0,0 +1,197 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package loadaware
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/koordinator-sh/koordinator/apis/extension"
+	slov1alpha1 "github.com/koordinator-sh/koordinator/apis/slo/v1alpha1"
+	schedulingconfig "github.com/koordinator-sh/koordinator/pkg/scheduler/apis/config"
+	"github.com/koordinator-sh/koordinator/pkg/util"
+)
+
+// NodeUsageEvaluator is the single entry point the loadaware Filter/Score extension points call
+// into: it resolves a node's usage-thresholds profile (helper.go) and evaluates it against the
+// node's aggregated usage as well as the PSI and extended-memory signals (signals.go). Keeping this
+// composition in one place means every threshold source is checked consistently instead of each
+// signal being wired into the plugin separately.
+type NodeUsageEvaluator struct {
+	// AggregationType is the fallback aggregated NodeMetric usage view used when args.Aggregated
+	// does not itself enable aggregation for the operation in question (see
+	// filterWithAggregation/scoreWithAggregation in helper.go), which otherwise supplies its own
+	// aggregation type per operation (UsageAggregationType for filtering, ScoreAggregationType for
+	// scoring).
+	AggregationType extension.AggregationType
+
+	// PSIThresholds and ExtendedMemoryThresholds are optional; a nil value disables that signal.
+	PSIThresholds            *PSIThresholds
+	ExtendedMemoryThresholds *ExtendedMemoryThresholds
+
+	// Estimators supplies the usage estimate for assumedPods (see FilterNode) that haven't shown up
+	// in the node's NodeMetric yet. A nil chain disables estimation, so not-yet-reported pods add
+	// nothing to the node's usage.
+	Estimators *EstimatorChain
+	// Historical, if set, is fed the node's latest PodsMetric report on every FilterNode call so its
+	// estimates improve as NodeMetric reports accumulate. It is typically also one of the estimators
+	// referenced by Estimators.
+	Historical *HistoricalEstimator
+
+	// BalanceWeights and BalanceWeightPercent configure the dominant-resource-spread balance score
+	// ScoreNode blends in alongside the usage-based score; see balance.go.
+	BalanceWeights       BalanceWeights
+	BalanceWeightPercent int64
+
+	// ScopedThresholds generalizes usage thresholds to per-QoS/priority/phase selectors (see
+	// qosthresholds.go). The first entry whose Selector matches the pod being scheduled overrides
+	// the node's usage-thresholds profile for that pod; if none match, FilterNode falls back to
+	// the profile's thresholds as chosen by selectUsageThresholds.
+	ScopedThresholds []ScopedUsageThresholds
+}
+
+// ScoreNode returns a 0-100 score for placing a pod requesting podRequests on node, blending how
+// lightly loaded the node is (usageBasedScore) with how evenly the placement would spread usage
+// across resources (computeImbalanceScore), per BalanceWeightPercent, then scaling the result down by
+// the node's PSI and extended-memory pressure the same way FilterNode filters on them, so two nodes
+// with identical cgroup usage but different pressure don't score the same. psi and reclaimableCache
+// are as in FilterNode. assumedPods and priorityOf are as in FilterNode.
+func (e *NodeUsageEvaluator) ScoreNode(node *corev1.Node, nodeMetric *slov1alpha1.NodeMetric, args *schedulingconfig.LoadAwareSchedulingArgs, podRequests corev1.ResourceList, psi *slov1alpha1.PSIMetric, reclaimableCache resource.Quantity, assumedPods []*corev1.Pod, priorityOf func(*corev1.Pod) extension.PriorityClass) int64 {
+	aggregationType := e.AggregationType
+	var aggregatedDuration *metav1.Duration
+	if scoreWithAggregation(args.Aggregated) {
+		aggregationType = args.Aggregated.ScoreAggregationType
+		aggregatedDuration = &args.Aggregated.UsageAggregatedDuration
+	}
+	usage := getTargetAggregatedUsage(nodeMetric, aggregatedDuration, aggregationType)
+	usage = withEstimatedUsage(usage, EstimateAssumedPodsUsage(nodeMetric, assumedPods, priorityOf, e.Estimators))
+
+	// The base usage score considers every resource usage/podRequests reports, independent of
+	// BalanceWeights -- that field only selects which resources the balance score below spreads
+	// across, and is commonly left unset when an operator hasn't opted into balance scoring.
+	usageScore := usageBasedScore(computeUtilizations(usage, node.Status.Allocatable, podRequests, nil))
+	imbalanceScore := computeImbalanceScore(usage, node.Status.Allocatable, podRequests, e.BalanceWeights)
+	score := combineScores(usageScore, imbalanceScore, e.BalanceWeightPercent)
+
+	pressureScore := psiPressureScore(psi, e.PSIThresholds)
+	if s := extendedMemoryPressureScore(usage, node.Status.Allocatable, reclaimableCache, e.ExtendedMemoryThresholds); s < pressureScore {
+		pressureScore = s
+	}
+	return score * pressureScore / 100
+}
+
+// FilterNode reports whether node passes the evaluator's usage, PSI and extended-memory thresholds
+// for pod, a candidate of QoS class podQoS and priority class podPriority. psi and reclaimableCache
+// reflect the node's latest reported signals; the caller (the plugin's Filter extension point) is
+// responsible for sourcing them from the node's NodeMetric. assumedPods are pods already assigned to
+// the node by a preceding scheduling decision in this cycle; any of them not yet present in
+// nodeMetric.Status.PodsMetric have their usage estimated via Estimators and folded into the node's
+// usage before it is checked against the thresholds.
+func (e *NodeUsageEvaluator) FilterNode(node *corev1.Node, nodeMetric *slov1alpha1.NodeMetric, pod *corev1.Pod, podQoS extension.QoSClass, podPriority extension.PriorityClass, args *schedulingconfig.LoadAwareSchedulingArgs, psi *slov1alpha1.PSIMetric, reclaimableCache resource.Quantity, assumedPods []*corev1.Pod, priorityOf func(*corev1.Pod) extension.PriorityClass) (bool, string) {
+	if e.Historical != nil {
+		feedHistoricalEstimator(e.Historical, nodeMetric, ownerUIDsOf(assumedPods))
+	}
+
+	profile := generateNodeUsageProfile(node, args, e.PSIThresholds, e.ExtendedMemoryThresholds)
+	aggregationType := e.AggregationType
+	var aggregatedDuration *metav1.Duration
+	if filterWithAggregation(args.Aggregated) {
+		aggregationType = args.Aggregated.UsageAggregationType
+		aggregatedDuration = &args.Aggregated.UsageAggregatedDuration
+	}
+	usage := getTargetAggregatedUsage(nodeMetric, aggregatedDuration, aggregationType)
+	usage = withEstimatedUsage(usage, EstimateAssumedPodsUsage(nodeMetric, assumedPods, priorityOf, e.Estimators))
+
+	if scoped := resolveUsageThresholds(pod, podQoS, podPriority, e.ScopedThresholds); scoped != nil {
+		scopedUsage := usage
+		if scoped.AggregationType != "" {
+			scopedUsage = getTargetAggregatedUsage(nodeMetric, aggregatedDuration, scoped.AggregationType)
+			scopedUsage = withEstimatedUsage(scopedUsage, EstimateAssumedPodsUsage(nodeMetric, assumedPods, priorityOf, e.Estimators))
+		}
+		if exceedsScopedUsageThresholds(nodeMetric, node.Status.Allocatable, scopedUsage, scoped) {
+			return false, "node usage exceeds the scoped usage thresholds resolved for this pod"
+		}
+	} else if usageThresholds := selectUsageThresholds(profile.usageThresholdsFilterProfile, podPriority); usage != nil && exceedsHighThresholds(usage, node.Status.Allocatable, usageThresholds) {
+		return false, "node usage exceeds configured usage thresholds"
+	}
+
+	if filterWithPSI(profile.PSI) && exceedsPSIThresholds(psi, profile.PSI) {
+		return false, "node PSI pressure exceeds configured thresholds"
+	}
+
+	if filterWithExtendedMemory(profile.ExtendedMemory) {
+		if exceedsExtendedMemoryThreshold(usage, node.Status.Allocatable, reclaimableCache, profile.ExtendedMemory) {
+			return false, "node extended memory usage exceeds configured threshold"
+		}
+	}
+
+	return true, ""
+}
+
+// withEstimatedUsage returns a copy of usage with estimated added on top, so estimated pod usage
+// counts toward the same thresholds as reported usage. usage is left untouched; a nil usage with a
+// non-empty estimate produces a fresh ResourceMap rather than being treated as "no usage data".
+func withEstimatedUsage(usage *slov1alpha1.ResourceMap, estimated corev1.ResourceList) *slov1alpha1.ResourceMap {
+	if len(estimated) == 0 {
+		return usage
+	}
+	result := &slov1alpha1.ResourceMap{ResourceList: make(corev1.ResourceList)}
+	if usage != nil {
+		for resourceName, quantity := range usage.ResourceList {
+			result.ResourceList[resourceName] = quantity
+		}
+	}
+	util.AddResourceList(result.ResourceList, estimated)
+	return result
+}
+
+// exceedsExtendedMemoryThreshold reports whether a node's extended memory usage (see
+// getExtendedMemoryUsage) breaches the configured percentage of allocatable memory.
+func exceedsExtendedMemoryThreshold(usage *slov1alpha1.ResourceMap, allocatable corev1.ResourceList, reclaimableCache resource.Quantity, thresholds *ExtendedMemoryThresholds) bool {
+	allocatableMemory, ok := allocatable[corev1.ResourceMemory]
+	if !ok || allocatableMemory.IsZero() || thresholds.MemoryThresholdPercent == nil {
+		return false
+	}
+	used := getExtendedMemoryUsage(usage, reclaimableCache)
+	percent := used * 100 / getResourceValue(corev1.ResourceMemory, allocatableMemory)
+	return percent >= *thresholds.MemoryThresholdPercent
+}
+
+// extendedMemoryPressureScore returns a 0-100 score, higher meaning less pressure, from the same
+// extended memory usage exceedsExtendedMemoryThreshold filters on, so ScoreNode can prefer nodes with
+// more reclaimed headroom well before they cross the configured threshold. A nil thresholds, an unset
+// MemoryThresholdPercent, or a missing/zero allocatable memory entry doesn't contribute to the score.
+func extendedMemoryPressureScore(usage *slov1alpha1.ResourceMap, allocatable corev1.ResourceList, reclaimableCache resource.Quantity, thresholds *ExtendedMemoryThresholds) int64 {
+	if thresholds == nil || thresholds.MemoryThresholdPercent == nil || *thresholds.MemoryThresholdPercent <= 0 {
+		return 100
+	}
+	allocatableMemory, ok := allocatable[corev1.ResourceMemory]
+	if !ok || allocatableMemory.IsZero() {
+		return 100
+	}
+	used := getExtendedMemoryUsage(usage, reclaimableCache)
+	percent := used * 100 / getResourceValue(corev1.ResourceMemory, allocatableMemory)
+	score := 100 - percent*100/(*thresholds.MemoryThresholdPercent)
+	if score < 0 {
+		score = 0
+	}
+	if score > 100 {
+		score = 100
+	}
+	return score
+}
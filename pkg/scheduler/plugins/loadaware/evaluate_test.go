@@ -0,0 +1,47 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package loadaware
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	slov1alpha1 "github.com/koordinator-sh/koordinator/apis/slo/v1alpha1"
+)
+
+func TestExtendedMemoryPressureScore(t *testing.T) {
+	allocatable := corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("100Gi")}
+	usage := &slov1alpha1.ResourceMap{ResourceList: corev1.ResourceList{
+		corev1.ResourceMemory: resource.MustParse("60Gi"),
+	}}
+
+	// No thresholds configured doesn't penalize the score.
+	assert.Equal(t, int64(100), extendedMemoryPressureScore(usage, allocatable, resource.Quantity{}, nil))
+	assert.Equal(t, int64(100), extendedMemoryPressureScore(usage, allocatable, resource.Quantity{}, &ExtendedMemoryThresholds{}))
+
+	thresholds := &ExtendedMemoryThresholds{MemoryThresholdPercent: int64Ptr(80)}
+	// 60% extended usage against an 80% threshold is 75% of the way there, scoring 25.
+	assert.Equal(t, int64(25), extendedMemoryPressureScore(usage, allocatable, resource.Quantity{}, thresholds))
+	// Reclaiming cache lowers extended usage and so raises the score.
+	assert.Equal(t, int64(50), extendedMemoryPressureScore(usage, allocatable, resource.MustParse("20Gi"), thresholds))
+	// Usage past the threshold clamps at 0 rather than going negative.
+	over := &slov1alpha1.ResourceMap{ResourceList: corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("100Gi")}}
+	assert.Equal(t, int64(0), extendedMemoryPressureScore(over, allocatable, resource.Quantity{}, thresholds))
+}
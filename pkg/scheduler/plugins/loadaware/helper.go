@@ -102,8 +102,40 @@ func scoreWithAggregation(args *schedulingconfig.LoadAwareSchedulingAggregatedAr
 	return args != nil && args.ScoreAggregationType != ""
 }
 
+// filterWithPSI reports whether PSI-based filtering (see signals.go) is enabled, mirroring how
+// filterWithAggregation gates the aggregated-usage filtering mode.
+func filterWithPSI(thresholds *PSIThresholds) bool {
+	return thresholds != nil
+}
+
+// filterWithExtendedMemory reports whether extended-memory-based filtering (see signals.go) is
+// enabled, mirroring how filterWithAggregation gates the aggregated-usage filtering mode.
+func filterWithExtendedMemory(thresholds *ExtendedMemoryThresholds) bool {
+	return thresholds != nil && thresholds.MemoryThresholdPercent != nil
+}
+
 type usageThresholdsFilterProfile = extension.CustomUsageThresholds
 
+// nodeUsageProfile bundles the usage-thresholds profile resolved for a node together with the PSI
+// and extended-memory signal thresholds configured for the plugin, so a single FilterNode call
+// resolves all of a node's per-node overrides and plugin-level signal thresholds together.
+type nodeUsageProfile struct {
+	*usageThresholdsFilterProfile
+	PSI            *PSIThresholds
+	ExtendedMemory *ExtendedMemoryThresholds
+}
+
+// generateNodeUsageProfile extends generateUsageThresholdsFilterProfile with the PSI and
+// extended-memory thresholds configured on the plugin, so PSI/extended-memory aware filtering (see
+// signals.go) shares the same per-node profile resolution as the existing usage thresholds.
+func generateNodeUsageProfile(node *corev1.Node, args *schedulingconfig.LoadAwareSchedulingArgs, psi *PSIThresholds, extendedMemory *ExtendedMemoryThresholds) *nodeUsageProfile {
+	return &nodeUsageProfile{
+		usageThresholdsFilterProfile: generateUsageThresholdsFilterProfile(node, args),
+		PSI:                          psi,
+		ExtendedMemory:               extendedMemory,
+	}
+}
+
 func generateUsageThresholdsFilterProfile(node *corev1.Node, args *schedulingconfig.LoadAwareSchedulingArgs) *usageThresholdsFilterProfile {
 	usageThresholds, prodUsageThresholds := args.UsageThresholds, args.ProdUsageThresholds
 	customUsageThresholds, err := extension.GetCustomUsageThresholds(node)
@@ -144,6 +176,17 @@ func generateUsageThresholdsFilterProfile(node *corev1.Node, args *schedulingcon
 	return customUsageThresholds
 }
 
+// selectUsageThresholds returns profile.ProdUsageThresholds for a Prod-priority pod when the profile
+// has one configured, else profile.UsageThresholds -- so a Prod pod keeps its dedicated, typically
+// stricter thresholds when it falls through to the profile-level default rather than matching a
+// ScopedThresholds entry.
+func selectUsageThresholds(profile *usageThresholdsFilterProfile, podPriority extension.PriorityClass) map[corev1.ResourceName]int64 {
+	if podPriority == extension.PriorityProd && len(profile.ProdUsageThresholds) > 0 {
+		return profile.ProdUsageThresholds
+	}
+	return profile.UsageThresholds
+}
+
 func getResourceValue(resourceName corev1.ResourceName, quantity resource.Quantity) int64 {
 	if resourceName == corev1.ResourceCPU {
 		return quantity.MilliValue()
@@ -151,13 +194,16 @@ func getResourceValue(resourceName corev1.ResourceName, quantity resource.Quanti
 	return quantity.Value()
 }
 
-func buildPodMetricMap(nodeMetric *slov1alpha1.NodeMetric, filterProdPod bool) map[types.NamespacedName]corev1.ResourceList {
+// buildPodMetricMap indexes a NodeMetric's reported pod usages by name. When excludeProdPod is set,
+// pods with PriorityProd are left out of the result, e.g. so eviction can be restricted to BE/LS
+// candidates without touching production workloads.
+func buildPodMetricMap(nodeMetric *slov1alpha1.NodeMetric, excludeProdPod bool) map[types.NamespacedName]corev1.ResourceList {
 	if len(nodeMetric.Status.PodsMetric) == 0 {
 		return nil
 	}
 	podMetrics := make(map[types.NamespacedName]corev1.ResourceList)
 	for _, podMetric := range nodeMetric.Status.PodsMetric {
-		if filterProdPod && podMetric.Priority != extension.PriorityProd {
+		if excludeProdPod && podMetric.Priority == extension.PriorityProd {
 			continue
 		}
 		name := types.NamespacedName{
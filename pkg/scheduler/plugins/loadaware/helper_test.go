@@ -0,0 +1,42 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package loadaware
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/koordinator-sh/koordinator/apis/extension"
+)
+
+func TestSelectUsageThresholds(t *testing.T) {
+	profile := &usageThresholdsFilterProfile{
+		UsageThresholds:     map[corev1.ResourceName]int64{corev1.ResourceCPU: 70},
+		ProdUsageThresholds: map[corev1.ResourceName]int64{corev1.ResourceCPU: 60},
+	}
+
+	// A Prod pod gets its dedicated thresholds instead of the profile-wide default.
+	assert.Equal(t, profile.ProdUsageThresholds, selectUsageThresholds(profile, extension.PriorityProd))
+	// Any other priority class falls back to the profile-wide default.
+	assert.Equal(t, profile.UsageThresholds, selectUsageThresholds(profile, extension.PriorityBE))
+
+	// A profile without dedicated Prod thresholds falls back to the default even for a Prod pod.
+	noProdOverride := &usageThresholdsFilterProfile{UsageThresholds: profile.UsageThresholds}
+	assert.Equal(t, profile.UsageThresholds, selectUsageThresholds(noProdOverride, extension.PriorityProd))
+}
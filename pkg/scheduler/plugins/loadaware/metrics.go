@@ -0,0 +1,49 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package loadaware
+
+import "github.com/prometheus/client_golang/prometheus"
+
+// reschedulingEvictionsTotal counts pod evictions performed by the low-node-utilization rescheduling
+// controller, labeled by the strategy that triggered the eviction and the source node.
+var reschedulingEvictionsTotal = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Namespace: "koordinator",
+		Subsystem: "scheduler",
+		Name:      "loadaware_rescheduling_evictions_total",
+		Help:      "Number of pods evicted by the load-aware rescheduling controller, by strategy and node.",
+	},
+	[]string{"strategy", "node"},
+)
+
+func init() {
+	prometheus.MustRegister(reschedulingEvictionsTotal)
+}
+
+// prometheusReschedulingMetricsRecorder is the default ReschedulingMetricsRecorder, backed by a
+// Prometheus counter registered against the process's default registry.
+type prometheusReschedulingMetricsRecorder struct{}
+
+// NewPrometheusReschedulingMetricsRecorder returns a ReschedulingMetricsRecorder that reports
+// eviction counts through Prometheus, ready to be passed to NewController.
+func NewPrometheusReschedulingMetricsRecorder() ReschedulingMetricsRecorder {
+	return &prometheusReschedulingMetricsRecorder{}
+}
+
+func (r *prometheusReschedulingMetricsRecorder) RecordEviction(strategy, node string) {
+	reschedulingEvictionsTotal.WithLabelValues(strategy, node).Inc()
+}
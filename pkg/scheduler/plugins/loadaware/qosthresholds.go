@@ -0,0 +1,134 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package loadaware
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/koordinator-sh/koordinator/apis/extension"
+	slov1alpha1 "github.com/koordinator-sh/koordinator/apis/slo/v1alpha1"
+	"github.com/koordinator-sh/koordinator/pkg/util"
+)
+
+// UsageThresholdsSelector narrows a ScopedUsageThresholds entry to the pods it applies to. A nil
+// field means "match any value" for that dimension.
+type UsageThresholdsSelector struct {
+	// QoSClass matches the Koordinator QoS class (LSR/LS/BE) recorded on the pod.
+	QoSClass *extension.QoSClass
+	// PriorityClass matches the Koordinator priority class (Prod/Mid/Batch/Free) recorded on the pod.
+	PriorityClass *extension.PriorityClass
+	// PodPhase matches the pod's current status phase, e.g. Running or Pending.
+	PodPhase *corev1.PodPhase
+	// LabelSelector matches the pod's labels.
+	LabelSelector *metav1.LabelSelector
+}
+
+// ScopedUsageThresholds is one entry of the generalized usage-thresholds list: the thresholds and
+// aggregation to apply to pods matched by Selector. Entries are evaluated in order and the first
+// match wins, the same way a descheduler's PodLifeTime strategy resolves pods to a target status
+// phase.
+type ScopedUsageThresholds struct {
+	Selector        UsageThresholdsSelector
+	UsageThresholds map[corev1.ResourceName]int64
+	// AggregationType, if set, selects the aggregated NodeMetric usage view this entry's thresholds
+	// are checked against in place of the evaluator's; it has no effect when UsageQoSClasses is set.
+	AggregationType extension.AggregationType
+
+	// UsageQoSClasses names which QoS classes' summed pod usage UsageThresholds is checked against,
+	// instead of the node's overall aggregated usage. This is what lets an entry scoped to LS pods
+	// reject a node when BE usage alone pushes a resource over its threshold, while a BE pod being
+	// scheduled is unaffected because no entry in the list selects it for that check. An empty slice
+	// means "the node's overall aggregated usage", matching entries that don't care which workloads
+	// are contributing.
+	UsageQoSClasses []extension.QoSClass
+}
+
+// matchesSelector reports whether pod matches every non-nil dimension of selector.
+func matchesSelector(pod *corev1.Pod, podQoS extension.QoSClass, podPriority extension.PriorityClass, selector UsageThresholdsSelector) bool {
+	if selector.QoSClass != nil && *selector.QoSClass != podQoS {
+		return false
+	}
+	if selector.PriorityClass != nil && *selector.PriorityClass != podPriority {
+		return false
+	}
+	if selector.PodPhase != nil && *selector.PodPhase != pod.Status.Phase {
+		return false
+	}
+	if selector.LabelSelector != nil {
+		sel, err := metav1.LabelSelectorAsSelector(selector.LabelSelector)
+		if err != nil || !sel.Matches(labels.Set(pod.Labels)) {
+			return false
+		}
+	}
+	return true
+}
+
+// resolveUsageThresholds returns the first entry in entries whose selector matches pod, or nil if
+// none match, in which case the caller should fall back to the node's default thresholds.
+func resolveUsageThresholds(pod *corev1.Pod, podQoS extension.QoSClass, podPriority extension.PriorityClass, entries []ScopedUsageThresholds) *ScopedUsageThresholds {
+	for i := range entries {
+		if matchesSelector(pod, podQoS, podPriority, entries[i].Selector) {
+			return &entries[i]
+		}
+	}
+	return nil
+}
+
+// buildPodMetricMapByQoS splits a node's reported pod usages into per-QoS-class buckets so that a
+// scoped entry can check the usage of chosen QoS classes, e.g. reject a node for LS pods when BE
+// usage alone pushes it over a threshold while BE pods, which no such entry selects, are unaffected.
+func buildPodMetricMapByQoS(nodeMetric *slov1alpha1.NodeMetric) map[extension.QoSClass]map[types.NamespacedName]corev1.ResourceList {
+	if len(nodeMetric.Status.PodsMetric) == 0 {
+		return nil
+	}
+	result := make(map[extension.QoSClass]map[types.NamespacedName]corev1.ResourceList)
+	for _, podMetric := range nodeMetric.Status.PodsMetric {
+		bucket, ok := result[podMetric.QoSClass]
+		if !ok {
+			bucket = make(map[types.NamespacedName]corev1.ResourceList)
+			result[podMetric.QoSClass] = bucket
+		}
+		bucket[types.NamespacedName{Namespace: podMetric.Namespace, Name: podMetric.Name}] = podMetric.PodUsage.ResourceList
+	}
+	return result
+}
+
+// sumUsageByQoS sums the pod usage of the given QoS classes' buckets, e.g. to check only BE usage
+// against a threshold meant to protect LS/LSR pods from best-effort noise.
+func sumUsageByQoS(podMetricsByQoS map[extension.QoSClass]map[types.NamespacedName]corev1.ResourceList, qosClasses []extension.QoSClass) corev1.ResourceList {
+	usage := make(corev1.ResourceList)
+	for _, qosClass := range qosClasses {
+		for _, podUsage := range podMetricsByQoS[qosClass] {
+			util.AddResourceList(usage, podUsage)
+		}
+	}
+	return usage
+}
+
+// exceedsScopedUsageThresholds reports whether the node breaches entry's UsageThresholds, computing
+// usage from entry.UsageQoSClasses when set or falling back to defaultUsage (the node's overall
+// aggregated usage) otherwise.
+func exceedsScopedUsageThresholds(nodeMetric *slov1alpha1.NodeMetric, allocatable corev1.ResourceList, defaultUsage *slov1alpha1.ResourceMap, entry *ScopedUsageThresholds) bool {
+	usage := defaultUsage
+	if len(entry.UsageQoSClasses) > 0 {
+		usage = &slov1alpha1.ResourceMap{ResourceList: sumUsageByQoS(buildPodMetricMapByQoS(nodeMetric), entry.UsageQoSClasses)}
+	}
+	return exceedsHighThresholds(usage, allocatable, entry.UsageThresholds)
+}
@@ -0,0 +1,108 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package loadaware
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	"github.com/koordinator-sh/koordinator/apis/extension"
+	slov1alpha1 "github.com/koordinator-sh/koordinator/apis/slo/v1alpha1"
+)
+
+func qosClassPtr(v extension.QoSClass) *extension.QoSClass { return &v }
+
+func TestMatchesSelector(t *testing.T) {
+	pod := &corev1.Pod{Status: corev1.PodStatus{Phase: corev1.PodRunning}}
+
+	assert.True(t, matchesSelector(pod, extension.QoSLS, extension.PriorityProd, UsageThresholdsSelector{}))
+	assert.True(t, matchesSelector(pod, extension.QoSLS, extension.PriorityProd, UsageThresholdsSelector{QoSClass: qosClassPtr(extension.QoSLS)}))
+	assert.False(t, matchesSelector(pod, extension.QoSBE, extension.PriorityProd, UsageThresholdsSelector{QoSClass: qosClassPtr(extension.QoSLS)}))
+
+	runningPhase := corev1.PodRunning
+	assert.True(t, matchesSelector(pod, extension.QoSLS, extension.PriorityProd, UsageThresholdsSelector{PodPhase: &runningPhase}))
+	pendingPhase := corev1.PodPending
+	assert.False(t, matchesSelector(pod, extension.QoSLS, extension.PriorityProd, UsageThresholdsSelector{PodPhase: &pendingPhase}))
+}
+
+func TestResolveUsageThresholdsFirstMatchWins(t *testing.T) {
+	entries := []ScopedUsageThresholds{
+		{Selector: UsageThresholdsSelector{QoSClass: qosClassPtr(extension.QoSLS)}, UsageThresholds: map[corev1.ResourceName]int64{corev1.ResourceCPU: 90}},
+		{Selector: UsageThresholdsSelector{}, UsageThresholds: map[corev1.ResourceName]int64{corev1.ResourceCPU: 70}},
+	}
+	pod := &corev1.Pod{}
+
+	lsEntry := resolveUsageThresholds(pod, extension.QoSLS, extension.PriorityProd, entries)
+	assert.Equal(t, int64(90), lsEntry.UsageThresholds[corev1.ResourceCPU])
+
+	beEntry := resolveUsageThresholds(pod, extension.QoSBE, extension.PriorityBE, entries)
+	assert.Equal(t, int64(70), beEntry.UsageThresholds[corev1.ResourceCPU])
+
+	assert.Nil(t, resolveUsageThresholds(pod, extension.QoSLS, extension.PriorityProd, nil))
+}
+
+func TestExceedsScopedUsageThresholdsRejectsLSButAcceptsBE(t *testing.T) {
+	allocatable := corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("100")}
+	nodeMetric := &slov1alpha1.NodeMetric{Status: slov1alpha1.NodeMetricStatus{
+		PodsMetric: []*slov1alpha1.PodMetricInfo{
+			{Namespace: "ns", Name: "be-1", QoSClass: extension.QoSBE, PodUsage: slov1alpha1.ResourceMap{ResourceList: corev1.ResourceList{
+				corev1.ResourceCPU: resource.MustParse("95"),
+			}}},
+		},
+	}}
+
+	// An entry scoped to LS pods that checks BE's bucket should reject the node once BE usage
+	// alone crosses 90%, even though there's no aggregated/default usage supplied.
+	lsEntry := &ScopedUsageThresholds{
+		Selector:        UsageThresholdsSelector{QoSClass: qosClassPtr(extension.QoSLS)},
+		UsageThresholds: map[corev1.ResourceName]int64{corev1.ResourceCPU: 90},
+		UsageQoSClasses: []extension.QoSClass{extension.QoSBE},
+	}
+	assert.True(t, exceedsScopedUsageThresholds(nodeMetric, allocatable, nil, lsEntry))
+
+	entries := []ScopedUsageThresholds{*lsEntry}
+	bePod := &corev1.Pod{}
+	// The same rule set must not affect a BE candidate: no entry in the list selects BE pods, so
+	// resolveUsageThresholds falls through to nil and the caller falls back to default thresholds.
+	assert.Nil(t, resolveUsageThresholds(bePod, extension.QoSBE, extension.PriorityBE, entries))
+}
+
+func TestSumUsageByQoS(t *testing.T) {
+	nodeMetric := &slov1alpha1.NodeMetric{Status: slov1alpha1.NodeMetricStatus{
+		PodsMetric: []*slov1alpha1.PodMetricInfo{
+			{Namespace: "ns", Name: "be-1", QoSClass: extension.QoSBE, PodUsage: slov1alpha1.ResourceMap{ResourceList: corev1.ResourceList{
+				corev1.ResourceCPU: resource.MustParse("10"),
+			}}},
+			{Namespace: "ns", Name: "be-2", QoSClass: extension.QoSBE, PodUsage: slov1alpha1.ResourceMap{ResourceList: corev1.ResourceList{
+				corev1.ResourceCPU: resource.MustParse("20"),
+			}}},
+			{Namespace: "ns", Name: "ls-1", QoSClass: extension.QoSLS, PodUsage: slov1alpha1.ResourceMap{ResourceList: corev1.ResourceList{
+				corev1.ResourceCPU: resource.MustParse("100"),
+			}}},
+		},
+	}}
+
+	byQoS := buildPodMetricMapByQoS(nodeMetric)
+	beUsage := sumUsageByQoS(byQoS, []extension.QoSClass{extension.QoSBE})
+	assert.Equal(t, resource.MustParse("30").Value(), beUsage.Cpu().Value())
+
+	assert.Empty(t, sumUsageByQoS(byQoS, nil))
+	assert.Nil(t, buildPodMetricMapByQoS(&slov1alpha1.NodeMetric{}))
+}
@@ -0,0 +1,387 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package loadaware
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/klog/v2"
+
+	"github.com/koordinator-sh/koordinator/apis/extension"
+	slov1alpha1 "github.com/koordinator-sh/koordinator/apis/slo/v1alpha1"
+)
+
+// nodeUsageClass classifies a node according to the configured low/high utilization thresholds.
+type nodeUsageClass int
+
+const (
+	nodeUsageUnderutilized nodeUsageClass = iota
+	nodeUsageAppropriate
+	nodeUsageOverutilized
+)
+
+// LoadAwareReschedulingArgs configures the low-node-utilization rescheduling controller.
+//
+// It reuses the same aggregated NodeMetric data the loadaware scheduler plugin consumes so that
+// the rebalancing decisions stay consistent with the filtering/scoring behavior at scheduling time.
+type LoadAwareReschedulingArgs struct {
+	metav1.TypeMeta
+
+	// LowThresholds are the per-resource utilization percentages below which a node is
+	// considered under-utilized and becomes an eviction target for other nodes' pods.
+	LowThresholds map[corev1.ResourceName]int64
+	// HighThresholds are the per-resource utilization percentages above which a node is
+	// considered over-utilized and becomes a source of pods to evict.
+	HighThresholds map[corev1.ResourceName]int64
+
+	// AggregationType selects which aggregated usage view (e.g. AVG, P95) is used to classify nodes.
+	AggregationType extension.AggregationType
+	// AggregatedDuration selects the aggregation window to read from NodeMetric.
+	// If empty, the longest non-empty window reported by the node is used.
+	AggregatedDuration metav1.Duration
+
+	// NumberOfNodes, if greater than zero, is the minimum number of under-utilized nodes a run
+	// must find before it evicts anything.
+	NumberOfNodes int32
+	// MaxEvictionsPerRun caps the total number of pods evicted across all nodes in a single run.
+	MaxEvictionsPerRun int32
+
+	// EvictableNamespaces restricts eviction to a set of namespaces. An empty set means all namespaces.
+	EvictableNamespaces []string
+	// OnlyEvictBEPods, when set, skips pods with PriorityProd so only best-effort workloads are moved.
+	OnlyEvictBEPods bool
+
+	// DryRun disables the actual eviction call and only reports the pods that would have been evicted.
+	DryRun bool
+}
+
+// nodeUsageSnapshot captures a node's aggregated utilization used for classification and eviction
+// bookkeeping during a single rescheduling run.
+type nodeUsageSnapshot struct {
+	node        *corev1.Node
+	nodeMetric  *slov1alpha1.NodeMetric
+	usage       *slov1alpha1.ResourceMap
+	allocatable corev1.ResourceList
+	class       nodeUsageClass
+}
+
+// Controller periodically scans NodeMetrics, classifies nodes as under/appropriate/over-utilized,
+// and evicts candidate pods from over-utilized nodes so the scheduler can rebalance the cluster.
+type Controller struct {
+	client     kubernetes.Interface
+	args       *LoadAwareReschedulingArgs
+	podEvictor PodEvictor
+	metrics    ReschedulingMetricsRecorder
+}
+
+// PodEvictor abstracts the eviction call so it can be swapped out in tests. In DryRun mode the
+// controller does not call it at all.
+type PodEvictor interface {
+	Evict(ctx context.Context, pod *corev1.Pod) error
+}
+
+// NodeMetricsGetter supplies the cluster snapshot a rescheduling pass runs against: the candidate
+// nodes and their NodeMetrics. It is typically backed by the scheduler's shared informer caches, and
+// is what lets Start drive RunOnce on a schedule without the controller owning its own listers.
+type NodeMetricsGetter interface {
+	List(ctx context.Context) ([]*corev1.Node, map[string]*slov1alpha1.NodeMetric, error)
+}
+
+// ReschedulingMetricsRecorder records Prometheus metrics for the rescheduling controller.
+type ReschedulingMetricsRecorder interface {
+	RecordEviction(strategy, node string)
+}
+
+// NewController creates a new low-node-utilization rescheduling Controller.
+func NewController(client kubernetes.Interface, args *LoadAwareReschedulingArgs, podEvictor PodEvictor, metrics ReschedulingMetricsRecorder) *Controller {
+	return &Controller{
+		client:     client,
+		args:       args,
+		podEvictor: podEvictor,
+		metrics:    metrics,
+	}
+}
+
+// classifyNode determines which usage class a node falls into given the configured thresholds.
+func classifyNode(usage *slov1alpha1.ResourceMap, allocatable corev1.ResourceList, args *LoadAwareReschedulingArgs) nodeUsageClass {
+	if usage == nil {
+		return nodeUsageAppropriate
+	}
+
+	over, under, evaluated := false, true, false
+	for resourceName, quantity := range usage.ResourceList {
+		allocatableQuantity, ok := allocatable[resourceName]
+		if !ok || allocatableQuantity.IsZero() {
+			continue
+		}
+		usedValue := getResourceValue(resourceName, quantity)
+		totalValue := getResourceValue(resourceName, allocatableQuantity)
+		if totalValue == 0 {
+			continue
+		}
+		percent := usedValue * 100 / totalValue
+		evaluated = true
+
+		if highThreshold, ok := args.HighThresholds[resourceName]; ok && percent >= highThreshold {
+			over = true
+		}
+		if lowThreshold, ok := args.LowThresholds[resourceName]; ok && percent >= lowThreshold {
+			under = false
+		}
+	}
+
+	if !evaluated {
+		return nodeUsageAppropriate
+	}
+	if over {
+		return nodeUsageOverutilized
+	}
+	if under {
+		return nodeUsageUnderutilized
+	}
+	return nodeUsageAppropriate
+}
+
+// RunOnce performs a single rescheduling pass across the given nodes and their NodeMetrics.
+func (c *Controller) RunOnce(ctx context.Context, nodes []*corev1.Node, nodeMetrics map[string]*slov1alpha1.NodeMetric) error {
+	var snapshots []*nodeUsageSnapshot
+	var underutilizedCount int
+	for _, node := range nodes {
+		nodeMetric := nodeMetrics[node.Name]
+		if nodeMetric == nil {
+			continue
+		}
+		usage := getTargetAggregatedUsage(nodeMetric, &c.args.AggregatedDuration, c.args.AggregationType)
+		snapshot := &nodeUsageSnapshot{
+			node:        node,
+			nodeMetric:  nodeMetric,
+			usage:       usage,
+			allocatable: node.Status.Allocatable,
+		}
+		snapshot.class = classifyNode(usage, node.Status.Allocatable, c.args)
+		if snapshot.class == nodeUsageUnderutilized {
+			underutilizedCount++
+		}
+		snapshots = append(snapshots, snapshot)
+	}
+
+	if c.args.NumberOfNodes > 0 && int32(underutilizedCount) < c.args.NumberOfNodes {
+		klog.V(4).InfoS("not enough underutilized nodes to trigger rescheduling", "found", underutilizedCount, "required", c.args.NumberOfNodes)
+		return nil
+	}
+
+	var overutilized []*nodeUsageSnapshot
+	for _, snapshot := range snapshots {
+		if snapshot.class == nodeUsageOverutilized {
+			overutilized = append(overutilized, snapshot)
+		}
+	}
+	sort.Slice(overutilized, func(i, j int) bool {
+		return sumResourceValues(overutilized[i].usage) > sumResourceValues(overutilized[j].usage)
+	})
+
+	var evicted int32
+	for _, snapshot := range overutilized {
+		if c.args.MaxEvictionsPerRun > 0 && evicted >= c.args.MaxEvictionsPerRun {
+			break
+		}
+		remaining := int32(-1)
+		if c.args.MaxEvictionsPerRun > 0 {
+			remaining = c.args.MaxEvictionsPerRun - evicted
+		}
+		n, err := c.evictFromNode(ctx, snapshot, remaining)
+		if err != nil {
+			klog.ErrorS(err, "failed to evict pods from node", "node", snapshot.node.Name)
+			continue
+		}
+		evicted += n
+	}
+	return nil
+}
+
+// Start runs RunOnce on a fixed interval until ctx is canceled, fetching the current nodes and
+// NodeMetrics from getter on every tick. interval defaults to DefaultReschedulingInterval when not
+// positive. Start blocks the calling goroutine, matching the run-to-completion controllers elsewhere
+// in the scheduler; callers that need it non-blocking should invoke it with `go`.
+func (c *Controller) Start(ctx context.Context, getter NodeMetricsGetter, interval time.Duration) {
+	if interval <= 0 {
+		interval = DefaultReschedulingInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			nodes, nodeMetrics, err := getter.List(ctx)
+			if err != nil {
+				klog.ErrorS(err, "failed to list nodes and NodeMetrics for rescheduling")
+				continue
+			}
+			if err := c.RunOnce(ctx, nodes, nodeMetrics); err != nil {
+				klog.ErrorS(err, "rescheduling run failed")
+			}
+		}
+	}
+}
+
+// evictFromNode evicts pods from a single over-utilized node until its projected usage drops below
+// the configured high thresholds, the node runs out of evictable pods, or the run-wide cap is hit.
+func (c *Controller) evictFromNode(ctx context.Context, snapshot *nodeUsageSnapshot, maxEvictions int32) (int32, error) {
+	// buildPodMetricMap's excludeProdPod flag skips Prod pods, leaving only BE/LS candidates
+	// when the operator has restricted eviction to non-Prod workloads.
+	podMetrics := buildPodMetricMap(snapshot.nodeMetric, c.args.OnlyEvictBEPods)
+	if len(podMetrics) == 0 {
+		return 0, nil
+	}
+
+	pods, err := c.client.CoreV1().Pods(corev1.NamespaceAll).List(ctx, metav1.ListOptions{
+		FieldSelector: "spec.nodeName=" + snapshot.node.Name,
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	evictableNamespaces := sets.New(c.args.EvictableNamespaces...)
+
+	remainingUsage := snapshot.usage.DeepCopy()
+	var evicted int32
+	for i := range pods.Items {
+		if maxEvictions >= 0 && evicted >= maxEvictions {
+			break
+		}
+		pod := &pods.Items[i]
+		if !isEvictableNamespace(pod.Namespace, evictableNamespaces) {
+			continue
+		}
+		if isDaemonSetPod(pod.OwnerReferences) || isMirrorOrStaticPod(pod) {
+			continue
+		}
+		name := types.NamespacedName{Namespace: pod.Namespace, Name: pod.Name}
+		usage, ok := podMetrics[name]
+		if !ok {
+			continue
+		}
+
+		if !exceedsHighThresholds(remainingUsage, snapshot.allocatable, c.args.HighThresholds) {
+			break
+		}
+
+		if c.args.DryRun {
+			klog.InfoS("dry-run: would evict pod", "pod", name, "node", snapshot.node.Name)
+		} else if err := c.podEvictor.Evict(ctx, pod); err != nil {
+			klog.ErrorS(err, "failed to evict pod", "pod", name)
+			continue
+		}
+
+		podUsages, _ := sumPodUsages(map[types.NamespacedName]corev1.ResourceList{name: usage}, nil)
+		subtractResourceList(remainingUsage.ResourceList, podUsages)
+		evicted++
+		if c.metrics != nil {
+			c.metrics.RecordEviction("lowNodeUtilization", snapshot.node.Name)
+		}
+	}
+	return evicted, nil
+}
+
+func exceedsHighThresholds(usage *slov1alpha1.ResourceMap, allocatable corev1.ResourceList, highThresholds map[corev1.ResourceName]int64) bool {
+	if usage == nil {
+		return false
+	}
+	for resourceName, threshold := range highThresholds {
+		quantity, ok := usage.ResourceList[resourceName]
+		if !ok {
+			continue
+		}
+		allocatableQuantity, ok := allocatable[resourceName]
+		if !ok || allocatableQuantity.IsZero() {
+			continue
+		}
+		percent := getResourceValue(resourceName, quantity) * 100 / getResourceValue(resourceName, allocatableQuantity)
+		if percent >= threshold {
+			return true
+		}
+	}
+	return false
+}
+
+func subtractResourceList(total, delta corev1.ResourceList) {
+	for resourceName, quantity := range delta {
+		if existing, ok := total[resourceName]; ok {
+			existing.Sub(quantity)
+			total[resourceName] = existing
+		}
+	}
+}
+
+func sumResourceValues(usage *slov1alpha1.ResourceMap) int64 {
+	if usage == nil {
+		return 0
+	}
+	var sum int64
+	for resourceName, quantity := range usage.ResourceList {
+		sum += getResourceValue(resourceName, quantity)
+	}
+	return sum
+}
+
+// isEvictableNamespace reports whether a pod in namespace is eligible for eviction given the
+// configured EvictableNamespaces set. An empty set means every namespace is eligible.
+func isEvictableNamespace(namespace string, evictableNamespaces sets.Set[string]) bool {
+	return evictableNamespaces.Len() == 0 || evictableNamespaces.Has(namespace)
+}
+
+// isMirrorOrStaticPod returns true for static pods and their API mirror pods, which cannot be
+// evicted through the Kubernetes eviction API.
+func isMirrorOrStaticPod(pod *corev1.Pod) bool {
+	if _, ok := pod.Annotations[corev1.MirrorPodAnnotationKey]; ok {
+		return true
+	}
+	return false
+}
+
+// evictionPodEvictor evicts pods through the standard Kubernetes eviction API, honoring PodDisruptionBudgets.
+type evictionPodEvictor struct {
+	client kubernetes.Interface
+}
+
+// NewEvictionPodEvictor returns a PodEvictor backed by the Kubernetes eviction subresource.
+func NewEvictionPodEvictor(client kubernetes.Interface) PodEvictor {
+	return &evictionPodEvictor{client: client}
+}
+
+func (e *evictionPodEvictor) Evict(ctx context.Context, pod *corev1.Pod) error {
+	eviction := &policyv1.Eviction{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      pod.Name,
+			Namespace: pod.Namespace,
+		},
+	}
+	return e.client.PolicyV1().Evictions(pod.Namespace).Evict(ctx, eviction)
+}
+
+// DefaultReschedulingInterval is the default interval between rescheduling runs when not configured.
+const DefaultReschedulingInterval = time.Minute
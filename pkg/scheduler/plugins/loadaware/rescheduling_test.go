@@ -0,0 +1,213 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package loadaware
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/koordinator-sh/koordinator/apis/extension"
+	slov1alpha1 "github.com/koordinator-sh/koordinator/apis/slo/v1alpha1"
+)
+
+func TestClassifyNode(t *testing.T) {
+	allocatable := corev1.ResourceList{
+		corev1.ResourceCPU:    resource.MustParse("100"),
+		corev1.ResourceMemory: resource.MustParse("100Gi"),
+	}
+	args := &LoadAwareReschedulingArgs{
+		LowThresholds:  map[corev1.ResourceName]int64{corev1.ResourceCPU: 20, corev1.ResourceMemory: 20},
+		HighThresholds: map[corev1.ResourceName]int64{corev1.ResourceCPU: 80, corev1.ResourceMemory: 80},
+	}
+
+	tests := []struct {
+		name  string
+		usage *slov1alpha1.ResourceMap
+		want  nodeUsageClass
+	}{
+		{
+			name:  "nil usage is appropriate",
+			usage: nil,
+			want:  nodeUsageAppropriate,
+		},
+		{
+			name: "under every low threshold",
+			usage: &slov1alpha1.ResourceMap{ResourceList: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("10"),
+				corev1.ResourceMemory: resource.MustParse("10Gi"),
+			}},
+			want: nodeUsageUnderutilized,
+		},
+		{
+			name: "over a single high threshold",
+			usage: &slov1alpha1.ResourceMap{ResourceList: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("90"),
+				corev1.ResourceMemory: resource.MustParse("10Gi"),
+			}},
+			want: nodeUsageOverutilized,
+		},
+		{
+			name: "between thresholds is appropriate",
+			usage: &slov1alpha1.ResourceMap{ResourceList: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("50"),
+				corev1.ResourceMemory: resource.MustParse("50Gi"),
+			}},
+			want: nodeUsageAppropriate,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, classifyNode(tt.usage, allocatable, args))
+		})
+	}
+}
+
+func TestClassifyNodeZeroAllocatable(t *testing.T) {
+	args := &LoadAwareReschedulingArgs{
+		LowThresholds:  map[corev1.ResourceName]int64{corev1.ResourceCPU: 20},
+		HighThresholds: map[corev1.ResourceName]int64{corev1.ResourceCPU: 80},
+	}
+	usage := &slov1alpha1.ResourceMap{ResourceList: corev1.ResourceList{
+		corev1.ResourceCPU: resource.MustParse("5"),
+	}}
+	// A zero allocatable quantity for the only tracked resource must be ignored rather than
+	// causing a division by zero, leaving the node in the default appropriate class.
+	allocatable := corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("0")}
+	assert.Equal(t, nodeUsageAppropriate, classifyNode(usage, allocatable, args))
+}
+
+func TestExceedsHighThresholds(t *testing.T) {
+	allocatable := corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("100")}
+	thresholds := map[corev1.ResourceName]int64{corev1.ResourceCPU: 80}
+
+	assert.False(t, exceedsHighThresholds(nil, allocatable, thresholds))
+	assert.False(t, exceedsHighThresholds(&slov1alpha1.ResourceMap{}, allocatable, thresholds))
+	assert.True(t, exceedsHighThresholds(&slov1alpha1.ResourceMap{ResourceList: corev1.ResourceList{
+		corev1.ResourceCPU: resource.MustParse("90"),
+	}}, allocatable, thresholds))
+	assert.False(t, exceedsHighThresholds(&slov1alpha1.ResourceMap{ResourceList: corev1.ResourceList{
+		corev1.ResourceMemory: resource.MustParse("90Gi"),
+	}}, allocatable, thresholds))
+}
+
+func TestIsEvictableNamespace(t *testing.T) {
+	assert.True(t, isEvictableNamespace("default", sets.New[string]()))
+	assert.True(t, isEvictableNamespace("app", sets.New("app", "kube-system")))
+	assert.False(t, isEvictableNamespace("default", sets.New("app", "kube-system")))
+}
+
+func TestIsMirrorOrStaticPod(t *testing.T) {
+	assert.False(t, isMirrorOrStaticPod(&corev1.Pod{}))
+	mirror := &corev1.Pod{}
+	mirror.Annotations = map[string]string{corev1.MirrorPodAnnotationKey: "true"}
+	assert.True(t, isMirrorOrStaticPod(mirror))
+}
+
+// fakeNodeMetricsGetter returns a fixed snapshot and counts how many times it was called.
+type fakeNodeMetricsGetter struct {
+	calls int32
+}
+
+func (f *fakeNodeMetricsGetter) List(ctx context.Context) ([]*corev1.Node, map[string]*slov1alpha1.NodeMetric, error) {
+	atomic.AddInt32(&f.calls, 1)
+	return nil, nil, nil
+}
+
+func TestControllerStartTicksUntilCanceled(t *testing.T) {
+	c := NewController(nil, &LoadAwareReschedulingArgs{}, nil, nil)
+	getter := &fakeNodeMetricsGetter{}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 25*time.Millisecond)
+	defer cancel()
+
+	c.Start(ctx, getter, 5*time.Millisecond)
+
+	assert.GreaterOrEqual(t, atomic.LoadInt32(&getter.calls), int32(1))
+}
+
+// fakePodEvictor records the pods it was asked to evict instead of calling the eviction API.
+type fakePodEvictor struct {
+	evicted []types.NamespacedName
+}
+
+func (f *fakePodEvictor) Evict(ctx context.Context, pod *corev1.Pod) error {
+	f.evicted = append(f.evicted, types.NamespacedName{Namespace: pod.Namespace, Name: pod.Name})
+	return nil
+}
+
+func TestEvictFromNodeOnlyEvictBEPods(t *testing.T) {
+	node := &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "node-1"},
+		Status: corev1.NodeStatus{Allocatable: corev1.ResourceList{
+			corev1.ResourceCPU: resource.MustParse("100"),
+		}},
+	}
+	prodPod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "prod-pod"},
+		Spec:       corev1.PodSpec{NodeName: "node-1"},
+	}
+	bePod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "be-pod"},
+		Spec:       corev1.PodSpec{NodeName: "node-1"},
+	}
+	nodeMetric := &slov1alpha1.NodeMetric{
+		Status: slov1alpha1.NodeMetricStatus{
+			PodsMetric: []*slov1alpha1.PodMetricInfo{
+				{Namespace: "ns", Name: "prod-pod", Priority: extension.PriorityProd, PodUsage: slov1alpha1.ResourceMap{ResourceList: corev1.ResourceList{
+					corev1.ResourceCPU: resource.MustParse("50"),
+				}}},
+				{Namespace: "ns", Name: "be-pod", Priority: extension.PriorityBE, PodUsage: slov1alpha1.ResourceMap{ResourceList: corev1.ResourceList{
+					corev1.ResourceCPU: resource.MustParse("45"),
+				}}},
+			},
+		},
+	}
+	usage := &slov1alpha1.ResourceMap{ResourceList: corev1.ResourceList{
+		corev1.ResourceCPU: resource.MustParse("95"),
+	}}
+
+	client := fake.NewSimpleClientset(prodPod, bePod)
+	evictor := &fakePodEvictor{}
+	args := &LoadAwareReschedulingArgs{
+		HighThresholds:  map[corev1.ResourceName]int64{corev1.ResourceCPU: 80},
+		OnlyEvictBEPods: true,
+	}
+	c := NewController(client, args, evictor, nil)
+
+	evicted, err := c.evictFromNode(context.Background(), &nodeUsageSnapshot{
+		node:        node,
+		nodeMetric:  nodeMetric,
+		usage:       usage,
+		allocatable: node.Status.Allocatable,
+	}, -1)
+
+	assert.NoError(t, err)
+	assert.Equal(t, int32(1), evicted)
+	// The safety knob must protect the Prod pod, not the other way around: only the BE pod should
+	// ever be handed to the evictor.
+	assert.Equal(t, []types.NamespacedName{{Namespace: "ns", Name: "be-pod"}}, evictor.evicted)
+}
@@ -0,0 +1,136 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package loadaware
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	slov1alpha1 "github.com/koordinator-sh/koordinator/apis/slo/v1alpha1"
+)
+
+// PSIThresholds bounds the kernel pressure-stall-information figures a node is allowed to report
+// before it is filtered out, independent of its cgroup usage percentages. A node can look idle on
+// CPU/memory usage and still be thrashing, which raw usage thresholds alone cannot catch.
+type PSIThresholds struct {
+	// CPUSomeAvg10Threshold rejects nodes whose 10s average "some" CPU pressure exceeds this value (0-100).
+	CPUSomeAvg10Threshold *int64
+	// MemorySomeAvg10Threshold rejects nodes whose 10s average "some" memory pressure exceeds this value (0-100).
+	MemorySomeAvg10Threshold *int64
+	// MemoryFullAvg10Threshold rejects nodes whose 10s average "full" memory pressure exceeds this value (0-100).
+	MemoryFullAvg10Threshold *int64
+	// IOSomeAvg10Threshold rejects nodes whose 10s average "some" IO pressure exceeds this value (0-100).
+	IOSomeAvg10Threshold *int64
+}
+
+// ExtendedMemoryThresholds is like UsageThresholds but evaluated against "extended" memory usage,
+// i.e. working-set memory with reclaimable page cache counted as available rather than used. This
+// lets operators admit pods onto nodes that look memory-pressured only because of a large,
+// reclaimable page cache.
+type ExtendedMemoryThresholds struct {
+	MemoryThresholdPercent *int64
+}
+
+// exceedsPSIThresholds reports whether any PSI signal on the node breaches the configured thresholds.
+func exceedsPSIThresholds(psi *slov1alpha1.PSIMetric, thresholds *PSIThresholds) bool {
+	if psi == nil || thresholds == nil {
+		return false
+	}
+	if breach(thresholds.CPUSomeAvg10Threshold, psi.CPU.SomeAvg10) {
+		return true
+	}
+	if breach(thresholds.MemorySomeAvg10Threshold, psi.Memory.SomeAvg10) {
+		return true
+	}
+	if breach(thresholds.MemoryFullAvg10Threshold, psi.Memory.FullAvg10) {
+		return true
+	}
+	if breach(thresholds.IOSomeAvg10Threshold, psi.IO.SomeAvg10) {
+		return true
+	}
+	return false
+}
+
+func breach(threshold *int64, value int64) bool {
+	return threshold != nil && value >= *threshold
+}
+
+// psiPressureScore returns a 0-100 score, higher meaning less pressure, from the same PSI signals
+// exceedsPSIThresholds filters on. This lets ScoreNode prefer nodes with lower pressure well before
+// they cross the configured threshold, instead of only rejecting them once they do. A nil psi or
+// thresholds, or a signal with no threshold configured, doesn't contribute to the score.
+func psiPressureScore(psi *slov1alpha1.PSIMetric, thresholds *PSIThresholds) int64 {
+	if psi == nil || thresholds == nil {
+		return 100
+	}
+	var sum, count int64
+	if s, ok := pressureComponentScore(thresholds.CPUSomeAvg10Threshold, psi.CPU.SomeAvg10); ok {
+		sum += s
+		count++
+	}
+	if s, ok := pressureComponentScore(thresholds.MemorySomeAvg10Threshold, psi.Memory.SomeAvg10); ok {
+		sum += s
+		count++
+	}
+	if s, ok := pressureComponentScore(thresholds.MemoryFullAvg10Threshold, psi.Memory.FullAvg10); ok {
+		sum += s
+		count++
+	}
+	if s, ok := pressureComponentScore(thresholds.IOSomeAvg10Threshold, psi.IO.SomeAvg10); ok {
+		sum += s
+		count++
+	}
+	if count == 0 {
+		return 100
+	}
+	return sum / count
+}
+
+// pressureComponentScore scores a single PSI signal against its threshold as a 0-100 fraction of
+// headroom remaining, e.g. a value at 50% of the threshold scores 50. ok is false when threshold is
+// unset, meaning the signal doesn't participate in psiPressureScore's average.
+func pressureComponentScore(threshold *int64, value int64) (score int64, ok bool) {
+	if threshold == nil || *threshold <= 0 {
+		return 0, false
+	}
+	score = 100 - value*100/(*threshold)
+	if score < 0 {
+		score = 0
+	}
+	if score > 100 {
+		score = 100
+	}
+	return score, true
+}
+
+// getExtendedMemoryUsage computes a node's memory usage with reclaimable page cache treated as
+// available, falling back to the raw aggregated memory usage when the extended view hasn't been
+// reported (e.g. older node agents that don't collect working-set/cache breakdowns).
+func getExtendedMemoryUsage(usage *slov1alpha1.ResourceMap, reclaimableCache resource.Quantity) int64 {
+	if usage == nil {
+		return 0
+	}
+	memory, ok := usage.ResourceList[corev1.ResourceMemory]
+	if !ok {
+		return 0
+	}
+	used := getResourceValue(corev1.ResourceMemory, memory) - reclaimableCache.Value()
+	if used < 0 {
+		used = 0
+	}
+	return used
+}
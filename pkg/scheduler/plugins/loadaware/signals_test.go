@@ -0,0 +1,76 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package loadaware
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	slov1alpha1 "github.com/koordinator-sh/koordinator/apis/slo/v1alpha1"
+)
+
+func int64Ptr(v int64) *int64 { return &v }
+
+func TestExceedsPSIThresholds(t *testing.T) {
+	assert.False(t, exceedsPSIThresholds(nil, &PSIThresholds{CPUSomeAvg10Threshold: int64Ptr(50)}))
+	assert.False(t, exceedsPSIThresholds(&slov1alpha1.PSIMetric{}, nil))
+
+	psi := &slov1alpha1.PSIMetric{}
+	psi.CPU.SomeAvg10 = 40
+	psi.Memory.SomeAvg10 = 10
+	psi.Memory.FullAvg10 = 5
+	psi.IO.SomeAvg10 = 10
+
+	assert.False(t, exceedsPSIThresholds(psi, &PSIThresholds{CPUSomeAvg10Threshold: int64Ptr(50)}))
+	assert.True(t, exceedsPSIThresholds(psi, &PSIThresholds{CPUSomeAvg10Threshold: int64Ptr(40)}))
+	assert.True(t, exceedsPSIThresholds(psi, &PSIThresholds{MemoryFullAvg10Threshold: int64Ptr(5)}))
+	// A threshold on a signal that isn't breached must not trip the others.
+	assert.False(t, exceedsPSIThresholds(psi, &PSIThresholds{IOSomeAvg10Threshold: int64Ptr(90)}))
+}
+
+func TestPSIPressureScore(t *testing.T) {
+	// No thresholds configured (or no PSI reported) means the signal doesn't penalize the score.
+	assert.Equal(t, int64(100), psiPressureScore(nil, &PSIThresholds{CPUSomeAvg10Threshold: int64Ptr(50)}))
+	assert.Equal(t, int64(100), psiPressureScore(&slov1alpha1.PSIMetric{}, nil))
+
+	psi := &slov1alpha1.PSIMetric{}
+	psi.CPU.SomeAvg10 = 25
+
+	// Halfway to the threshold scores halfway between 0 and 100.
+	assert.Equal(t, int64(50), psiPressureScore(psi, &PSIThresholds{CPUSomeAvg10Threshold: int64Ptr(50)}))
+	// Past the threshold clamps at 0 rather than going negative.
+	psi.CPU.SomeAvg10 = 100
+	assert.Equal(t, int64(0), psiPressureScore(psi, &PSIThresholds{CPUSomeAvg10Threshold: int64Ptr(50)}))
+	// A signal with no threshold set doesn't drag down the average of the ones that do.
+	psi.CPU.SomeAvg10 = 0
+	assert.Equal(t, int64(100), psiPressureScore(psi, &PSIThresholds{CPUSomeAvg10Threshold: int64Ptr(50), IOSomeAvg10Threshold: nil}))
+}
+
+func TestGetExtendedMemoryUsage(t *testing.T) {
+	assert.Equal(t, int64(0), getExtendedMemoryUsage(nil, resource.MustParse("1Gi")))
+
+	usage := &slov1alpha1.ResourceMap{ResourceList: corev1.ResourceList{
+		corev1.ResourceMemory: resource.MustParse("10Gi"),
+	}}
+	assert.Equal(t, int64(0), getExtendedMemoryUsage(&slov1alpha1.ResourceMap{}, resource.MustParse("1Gi")))
+	assert.Equal(t, resource.MustParse("8Gi").Value(), getExtendedMemoryUsage(usage, resource.MustParse("2Gi")))
+	// Reclaimable cache larger than reported usage must clamp at zero rather than go negative.
+	assert.Equal(t, int64(0), getExtendedMemoryUsage(usage, resource.MustParse("20Gi")))
+}